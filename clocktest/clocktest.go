@@ -0,0 +1,116 @@
+// Package clocktest provides a fake implementation of [debounce.Clock] for
+// tests, so that code using debounce.WithClock can exercise timing-dependent
+// behavior deterministically instead of sleeping on real wall-clock time.
+package clocktest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zmwangx/debounce"
+)
+
+// FakeClock is a [debounce.Clock] backed by a virtual clock that only moves
+// forward when [FakeClock.Advance] is called. It is safe for concurrent use.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+	nextSeq uint64
+}
+
+// NewFakeClock returns a FakeClock whose virtual clock starts at the Unix
+// epoch. The starting time is arbitrary and only matters relative to itself;
+// use Advance to move it forward.
+func NewFakeClock() *FakeClock {
+	return &FakeClock{now: time.Unix(0, 0)}
+}
+
+// Now returns the clock's current virtual time.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc schedules f to run once the virtual clock reaches now+d, as
+// observed by a call to Advance. It implements [debounce.Clock], except that,
+// unlike [time.AfterFunc], f runs synchronously on the goroutine calling
+// Advance rather than in a goroutine of its own.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) debounce.StoppableTimer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextSeq++
+	t := &fakeTimer{
+		clock:    c,
+		deadline: c.now.Add(d),
+		seq:      c.nextSeq,
+		f:        f,
+	}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// Advance moves the virtual clock forward by d, firing every timer scheduled
+// via AfterFunc whose deadline falls at or before the new time, in deadline
+// order (ties broken by scheduling order). A callback that schedules a new
+// timer itself becomes eligible to fire within the same Advance call if its
+// new deadline is still within range, matching debounce's internal pattern
+// of rescheduling its own timer from within the fired callback.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		next := c.nextDueLocked(target)
+		if next == nil {
+			c.now = target
+			c.mu.Unlock()
+			return
+		}
+		next.fired = true
+		c.now = next.deadline
+		c.mu.Unlock()
+
+		next.f()
+	}
+}
+
+func (c *FakeClock) nextDueLocked(target time.Time) *fakeTimer {
+	var next *fakeTimer
+	for _, t := range c.timers {
+		if t.fired || t.stopped || t.deadline.After(target) {
+			continue
+		}
+		if next == nil || t.deadline.Before(next.deadline) || (t.deadline.Equal(next.deadline) && t.seq < next.seq) {
+			next = t
+		}
+	}
+	return next
+}
+
+func (c *FakeClock) stop(t *fakeTimer) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t.fired || t.stopped {
+		return false
+	}
+	t.stopped = true
+	return true
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	seq      uint64
+	f        func()
+	fired    bool
+	stopped  bool
+}
+
+// Stop implements [debounce.StoppableTimer].
+func (t *fakeTimer) Stop() bool {
+	return t.clock.stop(t)
+}
@@ -0,0 +1,62 @@
+package clocktest_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"github.com/zmwangx/debounce/clocktest"
+)
+
+func TestFakeClock(t *testing.T) {
+	Convey("FakeClock", t, func() {
+		Convey("should not fire timers before their deadline", func() {
+			clock := clocktest.NewFakeClock()
+			fired := false
+			clock.AfterFunc(10*time.Millisecond, func() { fired = true })
+
+			clock.Advance(5 * time.Millisecond)
+			So(fired, ShouldBeFalse)
+
+			clock.Advance(5 * time.Millisecond)
+			So(fired, ShouldBeTrue)
+		})
+
+		Convey("should fire multiple due timers in deadline order", func() {
+			clock := clocktest.NewFakeClock()
+			var order []int
+			clock.AfterFunc(20*time.Millisecond, func() { order = append(order, 2) })
+			clock.AfterFunc(10*time.Millisecond, func() { order = append(order, 1) })
+
+			clock.Advance(30 * time.Millisecond)
+			So(order, ShouldResemble, []int{1, 2})
+		})
+
+		Convey("should let a fired callback reschedule within the same Advance", func() {
+			clock := clocktest.NewFakeClock()
+			count := 0
+			var reschedule func()
+			reschedule = func() {
+				count++
+				if count < 3 {
+					clock.AfterFunc(10*time.Millisecond, reschedule)
+				}
+			}
+			clock.AfterFunc(10*time.Millisecond, reschedule)
+
+			clock.Advance(30 * time.Millisecond)
+			So(count, ShouldEqual, 3)
+		})
+
+		Convey("should not fire a stopped timer", func() {
+			clock := clocktest.NewFakeClock()
+			fired := false
+			timer := clock.AfterFunc(10*time.Millisecond, func() { fired = true })
+
+			So(timer.Stop(), ShouldBeTrue)
+			clock.Advance(20 * time.Millisecond)
+			So(fired, ShouldBeFalse)
+			So(timer.Stop(), ShouldBeFalse)
+		})
+	})
+}
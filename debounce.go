@@ -13,13 +13,65 @@ import (
 )
 
 type options struct {
-	leading  bool
-	trailing bool
-	maxWait  time.Duration
+	leading            bool
+	trailing           bool
+	maxWait            time.Duration
+	clock              Clock
+	flushOnContextDone bool
+	maxBatchSize       int
+	onInvoke           func(InvocationInfo)
+	onCoalesce         func()
+	onCancel           func()
 }
 
 type Option func(*options)
 
+// Clock abstracts the time source used internally by
+// DebounceWithCustomSignature, so that debounced functions can be tested
+// without waiting on real wall-clock time. The default, used when no
+// [WithClock] option is given, wraps the real [time.Now] and [time.AfterFunc].
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc waits for d to elapse and then calls f in its own goroutine,
+	// returning a timer that can be stopped before it fires. It mirrors
+	// [time.AfterFunc].
+	AfterFunc(d time.Duration, f func()) StoppableTimer
+}
+
+// StoppableTimer is the subset of *[time.Timer]'s interface relied on by
+// debounce, implemented by whatever timer a [Clock] hands back from
+// AfterFunc.
+type StoppableTimer interface {
+	// Stop prevents the timer from firing, as with [time.Timer.Stop]. It
+	// returns true if the call stops the timer, false if the timer has
+	// already expired or been stopped.
+	Stop() bool
+}
+
+// RealClock is the default [Clock], backed by the real system clock ([time.Now]
+// and [time.AfterFunc]). It's exported so packages building on top of
+// debounce's Clock abstraction (such as debounce/ratelimit) can use it as
+// their own default instead of reimplementing the wrapper.
+type RealClock struct{}
+
+func (RealClock) Now() time.Time { return time.Now() }
+
+func (RealClock) AfterFunc(d time.Duration, f func()) StoppableTimer {
+	return time.AfterFunc(d, f)
+}
+
+// WithClock returns an Option that overrides the [Clock] used internally by
+// DebounceWithCustomSignature. The default is the real system clock. This is
+// primarily useful in tests, paired with a fake clock such as the one
+// provided by the debounce/clocktest subpackage, to exercise timing-dependent
+// behavior deterministically instead of sleeping on real wall-clock time.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
 // WithLeading returns an Option that sets whether the function is invoked on
 // the leading edge. The default is false.
 func WithLeading(leading bool) Option {
@@ -50,12 +102,18 @@ type Control struct {
 	Cancel  func()
 	Flush   func()
 	Pending func() bool
+	// Stats returns cumulative counters for this debounced function. See
+	// [Stats].
+	Stats func() Stats
 }
 
 type ControlWithReturnValue[T any] struct {
 	Cancel  func()
 	Flush   func() T
 	Pending func() bool
+	// Stats returns cumulative counters for this debounced function. See
+	// [Stats].
+	Stats func() Stats
 }
 
 // Debounce is a special case of [DebounceWithCustomSignature] where the
@@ -70,6 +128,7 @@ func Debounce(fn func(), wait time.Duration, opts ...Option) (debounced func(),
 		Cancel:  c.Cancel,
 		Flush:   func() { c.Flush() },
 		Pending: c.Pending,
+		Stats:   c.Stats,
 	}
 	return
 }
@@ -83,7 +142,8 @@ func Debounce(fn func(), wait time.Duration, opts ...Option) (debounced func(),
 // A control struct is also returned which comes with the following methods:
 //   - Cancel() cancels any pending invocation;
 //   - Flush() immediately invokes any pending invocation;
-//   - Pending() returns whether there is a pending invocation.
+//   - Pending() returns whether there is a pending invocation;
+//   - Stats() returns cumulative counters for this debounced function.
 //
 // The wait timeout should be positive.
 //
@@ -94,6 +154,9 @@ func Debounce(fn func(), wait time.Duration, opts ...Option) (debounced func(),
 //     the wait timeout.
 //   - [WithMaxWait]: the maximum time fn is allowed to be delayed before it's
 //     invoked.
+//   - [WithClock]: the [Clock] used internally, for testing.
+//   - [WithOnInvoke], [WithOnCoalesce], [WithOnCancel]: observability hooks
+//     called on invocation, coalescing, and cancellation respectively.
 //
 // If the leading and trailing options are both true, fn is invoked on the
 // trailing edge of the timeout only if the debounced function is invoked more
@@ -112,6 +175,7 @@ func DebounceWithCustomSignature[T1, T2 any](
 		leading:  false,
 		trailing: true,
 		maxWait:  0,
+		clock:    RealClock{},
 	}
 	for _, opt := range opts {
 		opt(o)
@@ -124,6 +188,10 @@ func DebounceWithCustomSignature[T1, T2 any](
 	if wait > maxWait {
 		maxWait = wait
 	}
+	clock := o.clock
+	onInvoke := o.onInvoke
+	onCoalesce := o.onCoalesce
+	onCancel := o.onCancel
 
 	// Locking is necessary in this Go port; the JS implementation is
 	// thread-safe only because JS is single-threaded.
@@ -131,39 +199,64 @@ func DebounceWithCustomSignature[T1, T2 any](
 
 	var lastCallTime time.Time
 	var lastInvokeTime time.Time
+	var windowStart time.Time
 	var lastArgs []T1
 	var lastArgsActive bool
-	var timer *time.Timer
+	var callsSinceInvoke int
+	var timer StoppableTimer
 	var result T2
+	var stats Stats
 
 	// A function named ...Locked is a function that must be called with the
 	// lock held.
-	var invokeFuncLocked func(time.Time) T2
+	var invokeFuncLocked func(t time.Time, edge Edge) T2
 	var leadingEdgeLocked func(time.Time) T2
 	var remainingWaitLocked func(time.Time) time.Duration
 	var shouldInvokeLocked func(time.Time) bool
 	var timerExpired func()
-	var trailingEdgeLocked func(time.Time) T2
+	var trailingEdgeLocked func(time.Time, Edge) T2
 	var cancel func()
 	var flush func() T2
 	var pending func() bool
+	var statsFunc func() Stats
 
-	invokeFuncLocked = func(t time.Time) T2 {
+	invokeFuncLocked = func(t time.Time, edge Edge) T2 {
+		coalesced := callsSinceInvoke
+		waited := t.Sub(windowStart)
 		lastInvokeTime = t
-		result = fn(lastArgs...)
+		callsSinceInvoke = 0
+		args := lastArgs
 		lastArgs = nil
 		lastArgsActive = false
+
+		start := time.Now()
+		result = fn(args...)
+		duration := time.Since(start)
+
+		stats.InvocationsMade++
+		if coalesced > stats.MaxCoalesceRatio {
+			stats.MaxCoalesceRatio = coalesced
+		}
+		if onInvoke != nil {
+			onInvoke(InvocationInfo{
+				Edge:      edge,
+				Coalesced: coalesced,
+				Wait:      waited,
+				Duration:  duration,
+			})
+		}
 		return result
 	}
 
 	leadingEdgeLocked = func(t time.Time) T2 {
 		// Reset any `maxWait` timer.
 		lastInvokeTime = t
+		windowStart = t
 		// Start the timer for the trailing edge.
-		timer = time.AfterFunc(wait, timerExpired)
+		timer = clock.AfterFunc(wait, timerExpired)
 		// Invoke the leading edge.
 		if leading {
-			return invokeFuncLocked(t)
+			return invokeFuncLocked(t, LeadingEdge)
 		}
 		return result
 	}
@@ -194,20 +287,26 @@ func DebounceWithCustomSignature[T1, T2 any](
 	timerExpired = func() {
 		lock.Lock()
 		defer lock.Unlock()
-		t := time.Now()
+		t := clock.Now()
 		if shouldInvokeLocked(t) {
-			trailingEdgeLocked(t)
+			// If the last call is still within wait, it's maxWait that
+			// elapsed, not activity that quieted down.
+			edge := TrailingEdge
+			if sinceLastCall := t.Sub(lastCallTime); sinceLastCall >= 0 && sinceLastCall < wait {
+				edge = MaxWaitEdge
+			}
+			trailingEdgeLocked(t, edge)
 			return
 		}
 		// Restart the timer.
-		timer = time.AfterFunc(remainingWaitLocked(t), timerExpired)
+		timer = clock.AfterFunc(remainingWaitLocked(t), timerExpired)
 	}
 
-	trailingEdgeLocked = func(t time.Time) T2 {
+	trailingEdgeLocked = func(t time.Time, edge Edge) T2 {
 		timer = nil
 		// Only invoke if `fn` has been debounced at least once.
 		if trailing && lastArgsActive {
-			return invokeFuncLocked(t)
+			return invokeFuncLocked(t, edge)
 		}
 		lastArgs = nil
 		lastArgsActive = false
@@ -217,6 +316,7 @@ func DebounceWithCustomSignature[T1, T2 any](
 	cancel = func() {
 		lock.Lock()
 		defer lock.Unlock()
+		hadPending := timer != nil
 		if timer != nil {
 			timer.Stop()
 		}
@@ -224,7 +324,14 @@ func DebounceWithCustomSignature[T1, T2 any](
 		lastInvokeTime = time.Time{}
 		lastArgs = nil
 		lastArgsActive = false
+		callsSinceInvoke = 0
 		timer = nil
+		if hadPending {
+			stats.Cancels++
+			if onCancel != nil {
+				onCancel()
+			}
+		}
 	}
 
 	flush = func() T2 {
@@ -233,7 +340,8 @@ func DebounceWithCustomSignature[T1, T2 any](
 		if timer == nil {
 			return result
 		}
-		return trailingEdgeLocked(time.Now())
+		stats.Flushes++
+		return trailingEdgeLocked(clock.Now(), TrailingEdge)
 	}
 
 	pending = func() bool {
@@ -242,26 +350,42 @@ func DebounceWithCustomSignature[T1, T2 any](
 		return timer != nil
 	}
 
+	statsFunc = func() Stats {
+		lock.RLock()
+		defer lock.RUnlock()
+		return stats
+	}
+
 	debounced = func(args ...T1) T2 {
 		lock.Lock()
 		defer lock.Unlock()
-		t := time.Now()
+		t := clock.Now()
+		stats.CallsReceived++
 		isInvoking := shouldInvokeLocked(t)
 		lastCallTime = t
 		lastArgs = args
 		lastArgsActive = true
+		callsSinceInvoke++
 		if isInvoking {
 			if timer == nil {
 				return leadingEdgeLocked(t)
 			}
 			if hasMaxWait {
-				// Handle invocations in a tight loop.
-				timer = time.AfterFunc(wait, timerExpired)
-				return invokeFuncLocked(t)
+				// Handle invocations in a tight loop. Invoke before resetting
+				// windowStart, so the reported wait reflects the window that
+				// just elapsed rather than the one about to start.
+				invokeFuncLocked(t, MaxWaitEdge)
+				windowStart = t
+				timer = clock.AfterFunc(wait, timerExpired)
+				return result
 			}
 		}
 		if timer == nil {
-			timer = time.AfterFunc(wait, timerExpired)
+			// A new window is opening with this call.
+			windowStart = t
+			timer = clock.AfterFunc(wait, timerExpired)
+		} else if onCoalesce != nil {
+			onCoalesce()
 		}
 		return result
 	}
@@ -269,6 +393,7 @@ func DebounceWithCustomSignature[T1, T2 any](
 		Cancel:  cancel,
 		Flush:   flush,
 		Pending: pending,
+		Stats:   statsFunc,
 	}
 	return
 }
@@ -0,0 +1,259 @@
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// WithMaxBatchSize returns an Option that makes [DebounceBatch] flush its
+// internal batch immediately once it reaches n coalesced calls, instead of
+// waiting for the trailing edge; the timer is then reset and a new batch
+// begins. A nonpositive value (the default) means no limit.
+func WithMaxBatchSize(n int) Option {
+	return func(o *options) {
+		o.maxBatchSize = n
+	}
+}
+
+// DebounceBatch is a variant of [DebounceWithCustomSignature] that, instead
+// of discarding all but the last call's arguments, accumulates every
+// intermediate call's arguments into a batch and invokes fn once with the
+// full batch when the trailing (or leading) edge fires. This suits debounced
+// writers, such as log flushers, bulk-insert coalescers, or network senders,
+// where dropping intermediate payloads would lose data.
+//
+// Options are the same as [DebounceWithCustomSignature]'s ([WithLeading],
+// [WithTrailing], [WithMaxWait], [WithClock]), plus [WithMaxBatchSize] to cap
+// how large a batch is allowed to grow before it's flushed early.
+func DebounceBatch[T1, T2 any](
+	fn func(batch [][]T1) T2,
+	wait time.Duration,
+	opts ...Option,
+) (debounced func(args ...T1) T2, control ControlWithReturnValue[T2]) {
+	o := &options{
+		leading:  false,
+		trailing: true,
+		maxWait:  0,
+		clock:    RealClock{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	leading := o.leading
+	trailing := o.trailing
+	hasMaxWait := o.maxWait > 0
+	maxWait := o.maxWait
+	if wait > maxWait {
+		maxWait = wait
+	}
+	maxBatchSize := o.maxBatchSize
+	clock := o.clock
+	onInvoke := o.onInvoke
+	onCoalesce := o.onCoalesce
+	onCancel := o.onCancel
+
+	var lock sync.RWMutex
+
+	var lastCallTime time.Time
+	var lastInvokeTime time.Time
+	var windowStart time.Time
+	var batch [][]T1
+	var timer StoppableTimer
+	var result T2
+	var stats Stats
+
+	// A function named ...Locked is a function that must be called with the
+	// lock held.
+	var invokeLocked func(t time.Time, edge Edge) T2
+	var leadingEdgeLocked func(time.Time) T2
+	var remainingWaitLocked func(time.Time) time.Duration
+	var shouldInvokeLocked func(time.Time) bool
+	var timerExpired func()
+	var trailingEdgeLocked func(time.Time, Edge) T2
+	var cancel func()
+	var flush func() T2
+	var pending func() bool
+	var statsFunc func() Stats
+
+	invokeLocked = func(t time.Time, edge Edge) T2 {
+		b := batch
+		batch = nil
+		coalesced := len(b)
+		waited := t.Sub(windowStart)
+		lastInvokeTime = t
+
+		start := time.Now()
+		result = fn(b)
+		duration := time.Since(start)
+
+		stats.InvocationsMade++
+		if coalesced > stats.MaxCoalesceRatio {
+			stats.MaxCoalesceRatio = coalesced
+		}
+		if onInvoke != nil {
+			onInvoke(InvocationInfo{
+				Edge:      edge,
+				Coalesced: coalesced,
+				Wait:      waited,
+				Duration:  duration,
+			})
+		}
+		return result
+	}
+
+	leadingEdgeLocked = func(t time.Time) T2 {
+		lastInvokeTime = t
+		windowStart = t
+		timer = clock.AfterFunc(wait, timerExpired)
+		if leading {
+			return invokeLocked(t, LeadingEdge)
+		}
+		return result
+	}
+
+	remainingWaitLocked = func(t time.Time) time.Duration {
+		timeSinceLastCall := t.Sub(lastCallTime)
+		timeSinceLastInvoke := t.Sub(lastInvokeTime)
+		timeWaiting := wait - timeSinceLastCall
+		if hasMaxWait && timeWaiting > maxWait-timeSinceLastInvoke {
+			return maxWait - timeSinceLastInvoke
+		}
+		return timeWaiting
+	}
+
+	shouldInvokeLocked = func(t time.Time) bool {
+		timeSinceLastCall := t.Sub(lastCallTime)
+		timeSinceLastInvoke := t.Sub(lastInvokeTime)
+		return lastCallTime.IsZero() || timeSinceLastCall >= wait || timeSinceLastCall < 0 || (hasMaxWait && timeSinceLastInvoke >= maxWait)
+	}
+
+	timerExpired = func() {
+		lock.Lock()
+		defer lock.Unlock()
+		t := clock.Now()
+		if shouldInvokeLocked(t) {
+			// If the last call is still within wait, it's maxWait that
+			// elapsed, not activity that quieted down.
+			edge := TrailingEdge
+			if sinceLastCall := t.Sub(lastCallTime); sinceLastCall >= 0 && sinceLastCall < wait {
+				edge = MaxWaitEdge
+			}
+			trailingEdgeLocked(t, edge)
+			return
+		}
+		timer = clock.AfterFunc(remainingWaitLocked(t), timerExpired)
+	}
+
+	trailingEdgeLocked = func(t time.Time, edge Edge) T2 {
+		timer = nil
+		if trailing && len(batch) > 0 {
+			return invokeLocked(t, edge)
+		}
+		batch = nil
+		return result
+	}
+
+	cancel = func() {
+		lock.Lock()
+		defer lock.Unlock()
+		hadPending := timer != nil
+		if timer != nil {
+			timer.Stop()
+		}
+		lastCallTime = time.Time{}
+		lastInvokeTime = time.Time{}
+		batch = nil
+		timer = nil
+		if hadPending {
+			stats.Cancels++
+			if onCancel != nil {
+				onCancel()
+			}
+		}
+	}
+
+	flush = func() T2 {
+		lock.Lock()
+		defer lock.Unlock()
+		if timer == nil {
+			return result
+		}
+		stats.Flushes++
+		return trailingEdgeLocked(clock.Now(), TrailingEdge)
+	}
+
+	pending = func() bool {
+		lock.RLock()
+		defer lock.RUnlock()
+		return timer != nil
+	}
+
+	statsFunc = func() Stats {
+		lock.RLock()
+		defer lock.RUnlock()
+		return stats
+	}
+
+	debounced = func(args ...T1) T2 {
+		lock.Lock()
+		defer lock.Unlock()
+		t := clock.Now()
+		stats.CallsReceived++
+		isInvoking := shouldInvokeLocked(t)
+		if timer == nil {
+			// A new window is opening with this call.
+			windowStart = t
+		}
+		lastCallTime = t
+		batch = append(batch, args)
+		opening := false
+		if isInvoking && timer == nil {
+			// A new window is opening with this call: route it through the
+			// leading edge first, so that WithLeading(true) combined with
+			// WithMaxBatchSize(n) reports LeadingEdge rather than
+			// MaxBatchSizeEdge when n is reached on the very first call. If
+			// leading invoked, the batch is already flushed; otherwise it
+			// falls through to the maxBatchSize check below with the timer
+			// now armed.
+			opening = true
+			result = leadingEdgeLocked(t)
+			if leading {
+				return result
+			}
+		} else if isInvoking && hasMaxWait {
+			// Handle invocations in a tight loop.
+			invokeLocked(t, MaxWaitEdge)
+			windowStart = t
+			timer = clock.AfterFunc(wait, timerExpired)
+			return result
+		}
+		if maxBatchSize > 0 && len(batch) >= maxBatchSize {
+			if timer != nil {
+				timer.Stop()
+			}
+			// Invoke before resetting windowStart, so the reported wait
+			// reflects the window that just elapsed rather than the one
+			// about to start.
+			invokeLocked(t, MaxBatchSizeEdge)
+			windowStart = t
+			timer = clock.AfterFunc(wait, timerExpired)
+			return result
+		}
+		if timer == nil {
+			// No invocation is pending yet: start the trailing-edge timer
+			// for the window this call opened.
+			timer = clock.AfterFunc(wait, timerExpired)
+		} else if !opening && onCoalesce != nil {
+			onCoalesce()
+		}
+		return result
+	}
+	control = ControlWithReturnValue[T2]{
+		Cancel:  cancel,
+		Flush:   flush,
+		Pending: pending,
+		Stats:   statsFunc,
+	}
+	return
+}
@@ -0,0 +1,81 @@
+package debounce_test
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	. "github.com/zmwangx/debounce"
+	"github.com/zmwangx/debounce/clocktest"
+)
+
+func TestDebounceBatch(t *testing.T) {
+	Convey("DebounceBatch", t, func() {
+		Convey("should accumulate all coalesced calls into one batch", func() {
+			clock := clocktest.NewFakeClock()
+			var batches [][][]string
+
+			debounced, _ := DebounceBatch(func(batch [][]string) interface{} {
+				batches = append(batches, batch)
+				return nil
+			}, 32*ms, WithClock(clock))
+
+			debounced("a")
+			debounced("b")
+			debounced("c")
+			So(batches, ShouldBeEmpty)
+
+			clock.Advance(32 * ms)
+			So(batches, ShouldResemble, [][][]string{{{"a"}, {"b"}, {"c"}}})
+		})
+
+		Convey("should flush early and start a new batch at WithMaxBatchSize", func() {
+			clock := clocktest.NewFakeClock()
+			var batches [][][]string
+
+			debounced, _ := DebounceBatch(func(batch [][]string) interface{} {
+				batches = append(batches, batch)
+				return nil
+			}, 32*ms, WithClock(clock), WithMaxBatchSize(2))
+
+			debounced("a")
+			debounced("b")
+			So(batches, ShouldResemble, [][][]string{{{"a"}, {"b"}}})
+
+			debounced("c")
+			clock.Advance(32 * ms)
+			So(batches, ShouldResemble, [][][]string{{{"a"}, {"b"}}, {{"c"}}})
+		})
+
+		Convey("should report LeadingEdge, not MaxBatchSizeEdge, when leading and maxBatchSize coincide", func() {
+			clock := clocktest.NewFakeClock()
+			var edges []Edge
+
+			debounced, _ := DebounceBatch(func(batch [][]string) interface{} {
+				return nil
+			}, 32*ms, WithClock(clock), WithLeading(true), WithMaxBatchSize(1),
+				WithOnInvoke(func(info InvocationInfo) { edges = append(edges, info.Edge) }))
+
+			debounced("a")
+			So(edges, ShouldResemble, []Edge{LeadingEdge})
+
+			clock.Advance(32 * ms)
+			So(edges, ShouldResemble, []Edge{LeadingEdge})
+		})
+
+		Convey("should support Flush", func() {
+			clock := clocktest.NewFakeClock()
+			var batches [][][]string
+
+			debounced, control := DebounceBatch(func(batch [][]string) interface{} {
+				batches = append(batches, batch)
+				return nil
+			}, 32*ms, WithClock(clock))
+
+			debounced("a")
+			debounced("b")
+			control.Flush()
+			So(batches, ShouldResemble, [][][]string{{{"a"}, {"b"}}})
+			So(control.Pending(), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,365 @@
+package debounce
+
+import (
+	"sync"
+	"time"
+)
+
+// keyedState holds the per-key debounce state for [DebounceBy]. Each key gets
+// its own mutex so that one busy key doesn't stall calls for other keys.
+type keyedState[T1, T2 any] struct {
+	mu sync.Mutex
+
+	lastCallTime   time.Time
+	lastInvokeTime time.Time
+	windowStart    time.Time
+	lastArgs       []T1
+	lastArgsActive bool
+	timer          StoppableTimer
+	result         T2
+	count          int
+}
+
+// ControlByKey is the control struct returned by [DebounceBy]. Unlike
+// [ControlWithReturnValue], every method is keyed, since the debounced
+// function maintains independent state per key.
+type ControlByKey[K comparable, T2 any] struct {
+	// Cancel cancels any pending invocation for key.
+	Cancel func(key K)
+	// CancelAll cancels all pending invocations across all keys.
+	CancelAll func()
+	// Flush immediately invokes any pending invocation for key.
+	Flush func(key K) T2
+	// FlushAll immediately invokes all pending invocations across all keys.
+	FlushAll func()
+	// Pending reports whether there is a pending invocation for key.
+	Pending func(key K) bool
+	// Stats returns cumulative counters across all keys. See [Stats].
+	Stats func() Stats
+}
+
+// DebounceBy creates a debounced function that debounces calls independently
+// per key, like [DebounceWithCustomSignature] but keyed. Calls are made as
+// debounced(key, args...); fn is invoked with the key, the number of calls
+// collapsed into this invocation since the key's last invocation, and the
+// most recent args passed for that key.
+//
+// Per-key state (last call/invoke times, timer, buffered args) is stored in
+// a map protected by a top-level mutex; each key's own state carries its own
+// mutex, so a burst of calls for one key never blocks debounced calls for
+// another key. Once a key's trailing edge fires with no new timer started in
+// the meantime, its state is removed from the map, so long-running programs
+// with high key cardinality don't accumulate unbounded state.
+//
+// The wait timeout should be positive. The same [Option]s accepted by
+// [DebounceWithCustomSignature] ([WithLeading], [WithTrailing],
+// [WithMaxWait], [WithClock], [WithOnInvoke], [WithOnCoalesce],
+// [WithOnCancel]) apply here, uniformly across all keys; the observability
+// hooks fire once per key event, and [ControlByKey.Stats] reports counters
+// aggregated across every key.
+func DebounceBy[K comparable, T1, T2 any](
+	fn func(key K, count int, args ...T1) T2,
+	wait time.Duration,
+	opts ...Option,
+) (debounced func(key K, args ...T1) T2, control ControlByKey[K, T2]) {
+	o := &options{
+		leading:  false,
+		trailing: true,
+		maxWait:  0,
+		clock:    RealClock{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	leading := o.leading
+	trailing := o.trailing
+	hasMaxWait := o.maxWait > 0
+	maxWait := o.maxWait
+	if wait > maxWait {
+		maxWait = wait
+	}
+	clock := o.clock
+	onInvoke := o.onInvoke
+	onCoalesce := o.onCoalesce
+	onCancel := o.onCancel
+
+	var statesLock sync.Mutex
+	states := make(map[K]*keyedState[T1, T2])
+
+	var statsLock sync.Mutex
+	var stats Stats
+
+	getOrCreate := func(key K) *keyedState[T1, T2] {
+		statesLock.Lock()
+		defer statesLock.Unlock()
+		s, ok := states[key]
+		if !ok {
+			s = &keyedState[T1, T2]{}
+			states[key] = s
+		}
+		return s
+	}
+
+	// gc removes s from the map if it's still the current state for key and
+	// it has gone idle (no pending timer) in the meantime.
+	gc := func(key K, s *keyedState[T1, T2]) {
+		statesLock.Lock()
+		defer statesLock.Unlock()
+		if cur, ok := states[key]; !ok || cur != s {
+			return
+		}
+		s.mu.Lock()
+		idle := s.timer == nil
+		s.mu.Unlock()
+		if idle {
+			delete(states, key)
+		}
+	}
+
+	// A function named ...Locked is a function that must be called with
+	// s.mu held.
+	var invokeLocked func(key K, s *keyedState[T1, T2], t time.Time, edge Edge) T2
+	var leadingEdgeLocked func(key K, s *keyedState[T1, T2], t time.Time) T2
+	var remainingWaitLocked func(s *keyedState[T1, T2], t time.Time) time.Duration
+	var shouldInvokeLocked func(s *keyedState[T1, T2], t time.Time) bool
+	var timerExpired func(key K, s *keyedState[T1, T2])
+	var trailingEdgeLocked func(key K, s *keyedState[T1, T2], t time.Time, edge Edge) T2
+
+	invokeLocked = func(key K, s *keyedState[T1, T2], t time.Time, edge Edge) T2 {
+		coalesced := s.count
+		waited := t.Sub(s.windowStart)
+		s.lastInvokeTime = t
+		s.count = 0
+		args := s.lastArgs
+		s.lastArgs = nil
+		s.lastArgsActive = false
+
+		start := time.Now()
+		s.result = fn(key, coalesced, args...)
+		duration := time.Since(start)
+
+		statsLock.Lock()
+		stats.InvocationsMade++
+		if coalesced > stats.MaxCoalesceRatio {
+			stats.MaxCoalesceRatio = coalesced
+		}
+		statsLock.Unlock()
+		if onInvoke != nil {
+			onInvoke(InvocationInfo{
+				Edge:      edge,
+				Coalesced: coalesced,
+				Wait:      waited,
+				Duration:  duration,
+			})
+		}
+		return s.result
+	}
+
+	leadingEdgeLocked = func(key K, s *keyedState[T1, T2], t time.Time) T2 {
+		s.lastInvokeTime = t
+		s.windowStart = t
+		s.timer = clock.AfterFunc(wait, func() { timerExpired(key, s) })
+		if leading {
+			return invokeLocked(key, s, t, LeadingEdge)
+		}
+		return s.result
+	}
+
+	remainingWaitLocked = func(s *keyedState[T1, T2], t time.Time) time.Duration {
+		timeSinceLastCall := t.Sub(s.lastCallTime)
+		timeSinceLastInvoke := t.Sub(s.lastInvokeTime)
+		timeWaiting := wait - timeSinceLastCall
+		if hasMaxWait && timeWaiting > maxWait-timeSinceLastInvoke {
+			return maxWait - timeSinceLastInvoke
+		}
+		return timeWaiting
+	}
+
+	shouldInvokeLocked = func(s *keyedState[T1, T2], t time.Time) bool {
+		timeSinceLastCall := t.Sub(s.lastCallTime)
+		timeSinceLastInvoke := t.Sub(s.lastInvokeTime)
+		return s.lastCallTime.IsZero() || timeSinceLastCall >= wait || timeSinceLastCall < 0 || (hasMaxWait && timeSinceLastInvoke >= maxWait)
+	}
+
+	timerExpired = func(key K, s *keyedState[T1, T2]) {
+		s.mu.Lock()
+		t := clock.Now()
+		if shouldInvokeLocked(s, t) {
+			// If the last call is still within wait, it's maxWait that
+			// elapsed, not activity that quieted down.
+			edge := TrailingEdge
+			if sinceLastCall := t.Sub(s.lastCallTime); sinceLastCall >= 0 && sinceLastCall < wait {
+				edge = MaxWaitEdge
+			}
+			trailingEdgeLocked(key, s, t, edge)
+			s.mu.Unlock()
+			gc(key, s)
+			return
+		}
+		s.timer = clock.AfterFunc(remainingWaitLocked(s, t), func() { timerExpired(key, s) })
+		s.mu.Unlock()
+	}
+
+	trailingEdgeLocked = func(key K, s *keyedState[T1, T2], t time.Time, edge Edge) T2 {
+		s.timer = nil
+		if trailing && s.lastArgsActive {
+			return invokeLocked(key, s, t, edge)
+		}
+		s.lastArgs = nil
+		s.lastArgsActive = false
+		s.count = 0
+		return s.result
+	}
+
+	debounced = func(key K, args ...T1) T2 {
+		s := getOrCreate(key)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		t := clock.Now()
+		statsLock.Lock()
+		stats.CallsReceived++
+		statsLock.Unlock()
+		isInvoking := shouldInvokeLocked(s, t)
+		s.lastCallTime = t
+		s.lastArgs = args
+		s.lastArgsActive = true
+		s.count++
+		if isInvoking {
+			if s.timer == nil {
+				return leadingEdgeLocked(key, s, t)
+			}
+			if hasMaxWait {
+				// Handle invocations in a tight loop. Invoke before resetting
+				// windowStart, so the reported wait reflects the window that
+				// just elapsed rather than the one about to start.
+				invokeLocked(key, s, t, MaxWaitEdge)
+				s.windowStart = t
+				s.timer = clock.AfterFunc(wait, func() { timerExpired(key, s) })
+				return s.result
+			}
+		}
+		if s.timer == nil {
+			// A new window is opening with this call.
+			s.windowStart = t
+			s.timer = clock.AfterFunc(wait, func() { timerExpired(key, s) })
+		} else if onCoalesce != nil {
+			onCoalesce()
+		}
+		return s.result
+	}
+
+	cancel := func(key K) {
+		statesLock.Lock()
+		s, ok := states[key]
+		statesLock.Unlock()
+		if !ok {
+			return
+		}
+		// Reset s in place rather than deleting it from the map first: a
+		// concurrent debounced(key, ...) call may already hold this same *s
+		// (fetched via getOrCreate) and be about to lock s.mu, and deleting
+		// states[key] out from under it would let that call re-arm a timer
+		// on a state object the map no longer tracks. Resetting under s.mu
+		// serializes against that call instead, and gc removes the entry
+		// once it's actually idle.
+		s.mu.Lock()
+		hadPending := s.timer != nil
+		if s.timer != nil {
+			s.timer.Stop()
+		}
+		s.lastCallTime = time.Time{}
+		s.lastInvokeTime = time.Time{}
+		s.lastArgs = nil
+		s.lastArgsActive = false
+		s.count = 0
+		s.timer = nil
+		s.mu.Unlock()
+		if hadPending {
+			statsLock.Lock()
+			stats.Cancels++
+			statsLock.Unlock()
+			if onCancel != nil {
+				onCancel()
+			}
+		}
+		gc(key, s)
+	}
+
+	cancelAll := func() {
+		// Cancel each key in place, for the same reason cancel doesn't
+		// delete from the map directly.
+		statesLock.Lock()
+		keys := make([]K, 0, len(states))
+		for k := range states {
+			keys = append(keys, k)
+		}
+		statesLock.Unlock()
+		for _, k := range keys {
+			cancel(k)
+		}
+	}
+
+	flush := func(key K) T2 {
+		statesLock.Lock()
+		s, ok := states[key]
+		statesLock.Unlock()
+		if !ok {
+			var zero T2
+			return zero
+		}
+		s.mu.Lock()
+		if s.timer == nil {
+			result := s.result
+			s.mu.Unlock()
+			return result
+		}
+		statsLock.Lock()
+		stats.Flushes++
+		statsLock.Unlock()
+		result := trailingEdgeLocked(key, s, clock.Now(), TrailingEdge)
+		s.mu.Unlock()
+		gc(key, s)
+		return result
+	}
+
+	flushAll := func() {
+		statesLock.Lock()
+		keys := make([]K, 0, len(states))
+		for k := range states {
+			keys = append(keys, k)
+		}
+		statesLock.Unlock()
+		for _, k := range keys {
+			flush(k)
+		}
+	}
+
+	pending := func(key K) bool {
+		statesLock.Lock()
+		s, ok := states[key]
+		statesLock.Unlock()
+		if !ok {
+			return false
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.timer != nil
+	}
+
+	statsFunc := func() Stats {
+		statsLock.Lock()
+		defer statsLock.Unlock()
+		return stats
+	}
+
+	control = ControlByKey[K, T2]{
+		Cancel:    cancel,
+		CancelAll: cancelAll,
+		Flush:     flush,
+		FlushAll:  flushAll,
+		Pending:   pending,
+		Stats:     statsFunc,
+	}
+	return
+}
@@ -0,0 +1,143 @@
+package debounce_test
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	. "github.com/zmwangx/debounce"
+	"github.com/zmwangx/debounce/clocktest"
+)
+
+func TestDebounceBy(t *testing.T) {
+	Convey("DebounceBy", t, func() {
+		Convey("should debounce independently per key", func() {
+			clock := clocktest.NewFakeClock()
+			counts := make(map[string]int)
+			lastCounts := make(map[string]int)
+
+			debounced, _ := DebounceBy(func(key string, count int, args ...string) interface{} {
+				counts[key]++
+				lastCounts[key] = count
+				return nil
+			}, 32*ms, WithClock(clock))
+
+			debounced("a")
+			debounced("a")
+			debounced("b")
+			So(counts["a"], ShouldEqual, 0)
+			So(counts["b"], ShouldEqual, 0)
+
+			clock.Advance(64 * ms)
+			So(counts["a"], ShouldEqual, 1)
+			So(counts["b"], ShouldEqual, 1)
+			So(lastCounts["a"], ShouldEqual, 2)
+			So(lastCounts["b"], ShouldEqual, 1)
+		})
+
+		Convey("should support Cancel and CancelAll per key", func() {
+			clock := clocktest.NewFakeClock()
+			callCount := 0
+
+			debounced, control := DebounceBy(func(key string, count int, args ...interface{}) interface{} {
+				callCount++
+				return nil
+			}, 32*ms, WithClock(clock))
+
+			debounced("a")
+			debounced("b")
+			control.Cancel("a")
+
+			clock.Advance(64 * ms)
+			So(callCount, ShouldEqual, 1)
+
+			debounced("a")
+			debounced("b")
+			control.CancelAll()
+
+			clock.Advance(64 * ms)
+			So(callCount, ShouldEqual, 1)
+		})
+
+		Convey("should support Flush and FlushAll per key", func() {
+			callCount := 0
+
+			debounced, control := DebounceBy(func(key string, count int, args ...interface{}) int {
+				callCount++
+				return callCount
+			}, 32*ms)
+
+			debounced("a")
+			debounced("b")
+			So(control.Flush("a"), ShouldEqual, 1)
+			So(control.Pending("a"), ShouldBeFalse)
+			So(control.Pending("b"), ShouldBeTrue)
+
+			control.FlushAll()
+			So(callCount, ShouldEqual, 2)
+			So(control.Pending("b"), ShouldBeFalse)
+		})
+
+		Convey("should noop Flush and report Pending false for unknown keys", func() {
+			debounced, control := DebounceBy(func(key string, count int, args ...interface{}) interface{} {
+				return nil
+			}, 32*ms)
+			debounced("a")
+
+			So(control.Pending("never-called"), ShouldBeFalse)
+			So(control.Flush("never-called"), ShouldBeNil)
+		})
+
+		Convey("should honor WithClock deterministically across keys", func() {
+			clock := clocktest.NewFakeClock()
+			counts := make(map[string]int)
+
+			debounced, _ := DebounceBy(func(key string, count int, args ...interface{}) interface{} {
+				counts[key]++
+				return nil
+			}, 32*ms, WithClock(clock))
+
+			debounced("a")
+			debounced("b")
+			So(counts["a"], ShouldEqual, 0)
+			So(counts["b"], ShouldEqual, 0)
+
+			clock.Advance(32 * ms)
+			So(counts["a"], ShouldEqual, 1)
+			So(counts["b"], ShouldEqual, 1)
+		})
+
+		Convey("should fire observability hooks and report Stats across all keys", func() {
+			clock := clocktest.NewFakeClock()
+			var invocations []InvocationInfo
+			coalesceCount := 0
+			cancelCount := 0
+
+			debounced, control := DebounceBy(func(key string, count int, args ...interface{}) interface{} {
+				return nil
+			}, 32*ms,
+				WithClock(clock),
+				WithOnInvoke(func(info InvocationInfo) { invocations = append(invocations, info) }),
+				WithOnCoalesce(func() { coalesceCount++ }),
+				WithOnCancel(func() { cancelCount++ }),
+			)
+
+			debounced("a")
+			debounced("a")
+			debounced("b")
+			So(coalesceCount, ShouldEqual, 1)
+
+			clock.Advance(32 * ms)
+			So(invocations, ShouldHaveLength, 2)
+
+			debounced("a")
+			control.Cancel("a")
+			So(cancelCount, ShouldEqual, 1)
+
+			stats := control.Stats()
+			So(stats.CallsReceived, ShouldEqual, 4)
+			So(stats.InvocationsMade, ShouldEqual, 2)
+			So(stats.Cancels, ShouldEqual, 1)
+			So(stats.MaxCoalesceRatio, ShouldEqual, 2)
+		})
+	})
+}
@@ -0,0 +1,53 @@
+package debounce_test
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	. "github.com/zmwangx/debounce"
+	"github.com/zmwangx/debounce/clocktest"
+)
+
+func TestDebounceWithCustomSignatureWithClock(t *testing.T) {
+	Convey("DebounceWithCustomSignature with a fake clock", t, func() {
+		Convey("should debounce deterministically against virtual time", func() {
+			clock := clocktest.NewFakeClock()
+			callCount := 0
+
+			debounced, _ := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
+				callCount++
+				return nil
+			}, 32*ms, WithClock(clock))
+
+			debounced()
+			debounced()
+			So(callCount, ShouldEqual, 0)
+
+			clock.Advance(31 * ms)
+			So(callCount, ShouldEqual, 0)
+
+			clock.Advance(1 * ms)
+			So(callCount, ShouldEqual, 1)
+		})
+
+		Convey("should honor maxWait against virtual time", func() {
+			clock := clocktest.NewFakeClock()
+			callCount := 0
+
+			debounced, _ := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
+				callCount++
+				return nil
+			}, 32*ms, WithClock(clock), WithMaxWait(64*ms))
+
+			debounced()
+			clock.Advance(20 * ms)
+			debounced()
+			clock.Advance(20 * ms)
+			debounced()
+			So(callCount, ShouldEqual, 0)
+
+			clock.Advance(24 * ms)
+			So(callCount, ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,81 @@
+package debounce
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ControlWithContext is the control struct returned by [DebounceContext].
+type ControlWithContext[T2 any] struct {
+	// Cancel cancels any pending invocation.
+	Cancel func()
+	// Flush immediately invokes any pending invocation.
+	Flush func() T2
+	// Pending returns whether there is a pending invocation.
+	Pending func() bool
+	// Wait blocks until any in-flight invocation of fn returns. It's meant
+	// for graceful shutdown: after the bound context is cancelled, Wait lets
+	// a caller drain an invocation that may already be running.
+	Wait func()
+	// Stats returns cumulative counters for this debounced function. See
+	// [Stats].
+	Stats func() Stats
+}
+
+// WithFlushOnContextDone returns an Option that controls what [DebounceContext]
+// does to a pending invocation when its context is cancelled: flush it
+// synchronously if flush is true, or cancel it (the default, flush is false).
+func WithFlushOnContextDone(flush bool) Option {
+	return func(o *options) {
+		o.flushOnContextDone = flush
+	}
+}
+
+// DebounceContext is a variant of [DebounceWithCustomSignature] whose
+// lifetime is bound to ctx. fn receives ctx on every invocation, so
+// long-running synchronous work can respect its deadline or cancellation.
+//
+// When ctx is done, any pending trailing invocation is cancelled, matching
+// [ControlWithContext.Cancel]; pass [WithFlushOnContextDone](true) to flush
+// it synchronously instead.
+//
+// The returned control additionally exposes Wait, which blocks until any
+// in-flight invocation of fn returns, so callers can drain gracefully during
+// shutdown.
+func DebounceContext[T1, T2 any](
+	ctx context.Context,
+	fn func(ctx context.Context, args ...T1) T2,
+	wait time.Duration,
+	opts ...Option,
+) (debounced func(args ...T1) T2, control ControlWithContext[T2]) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var wg sync.WaitGroup
+	debounced, inner := DebounceWithCustomSignature(func(args ...T1) T2 {
+		wg.Add(1)
+		defer wg.Done()
+		return fn(ctx, args...)
+	}, wait, opts...)
+
+	go func() {
+		<-ctx.Done()
+		if o.flushOnContextDone {
+			inner.Flush()
+			return
+		}
+		inner.Cancel()
+	}()
+
+	control = ControlWithContext[T2]{
+		Cancel:  inner.Cancel,
+		Flush:   inner.Flush,
+		Pending: inner.Pending,
+		Wait:    wg.Wait,
+		Stats:   inner.Stats,
+	}
+	return
+}
@@ -0,0 +1,109 @@
+package debounce_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	. "github.com/zmwangx/debounce"
+	"github.com/zmwangx/debounce/clocktest"
+)
+
+func TestDebounceContext(t *testing.T) {
+	Convey("DebounceContext", t, func() {
+		Convey("should debounce and pass ctx to fn", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			clock := clocktest.NewFakeClock()
+			callCount := 0
+			var seenCtx context.Context
+
+			debounced, _ := DebounceContext(ctx, func(ctx context.Context, args ...interface{}) interface{} {
+				callCount++
+				seenCtx = ctx
+				return nil
+			}, 32*ms, WithClock(clock))
+
+			debounced()
+			So(callCount, ShouldEqual, 0)
+
+			clock.Advance(32 * ms)
+			So(callCount, ShouldEqual, 1)
+			So(seenCtx, ShouldEqual, ctx)
+		})
+
+		Convey("should cancel a pending invocation when ctx is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			clock := clocktest.NewFakeClock()
+			callCount := 0
+
+			debounced, control := DebounceContext(ctx, func(ctx context.Context, args ...interface{}) interface{} {
+				callCount++
+				return nil
+			}, 32*ms, WithClock(clock))
+
+			debounced()
+			cancel()
+			// Give the watcher goroutine a chance to run.
+			for control.Pending() {
+				time.Sleep(time.Millisecond)
+			}
+
+			clock.Advance(64 * ms)
+			So(callCount, ShouldEqual, 0)
+		})
+
+		Convey("should flush a pending invocation on ctx done when WithFlushOnContextDone is set", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			clock := clocktest.NewFakeClock()
+			callCount := 0
+
+			debounced, control := DebounceContext(ctx, func(ctx context.Context, args ...interface{}) interface{} {
+				callCount++
+				return nil
+			}, 32*ms, WithClock(clock), WithFlushOnContextDone(true))
+
+			debounced()
+			cancel()
+			for control.Pending() {
+				time.Sleep(time.Millisecond)
+			}
+			control.Wait()
+			So(callCount, ShouldEqual, 1)
+		})
+
+		Convey("Wait should block until an in-flight invocation returns", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			clock := clocktest.NewFakeClock()
+			started := make(chan struct{})
+			release := make(chan struct{})
+
+			debounced, control := DebounceContext(ctx, func(ctx context.Context, args ...interface{}) interface{} {
+				close(started)
+				<-release
+				return nil
+			}, 32*ms, WithClock(clock))
+
+			debounced()
+			go clock.Advance(32 * ms)
+			<-started
+
+			done := make(chan struct{})
+			go func() {
+				control.Wait()
+				close(done)
+			}()
+
+			select {
+			case <-done:
+				t.Fatal("Wait returned before the in-flight invocation finished")
+			case <-time.After(20 * time.Millisecond):
+			}
+
+			close(release)
+			<-done
+		})
+	})
+}
@@ -0,0 +1,95 @@
+package debounce
+
+import "time"
+
+// Edge identifies which edge of the debounce window triggered an invocation.
+type Edge int
+
+const (
+	// LeadingEdge is reported when fn is invoked because of [WithLeading].
+	LeadingEdge Edge = iota
+	// TrailingEdge is reported when fn is invoked because of [WithTrailing],
+	// after activity has quieted down.
+	TrailingEdge
+	// MaxWaitEdge is reported when fn is invoked because [WithMaxWait] was
+	// reached while calls kept arriving in a tight loop.
+	MaxWaitEdge
+	// MaxBatchSizeEdge is reported when [DebounceBatch] invokes fn early
+	// because [WithMaxBatchSize] was reached.
+	MaxBatchSizeEdge
+)
+
+// String returns a lowercase name for e, e.g. "leading".
+func (e Edge) String() string {
+	switch e {
+	case LeadingEdge:
+		return "leading"
+	case TrailingEdge:
+		return "trailing"
+	case MaxWaitEdge:
+		return "maxWait"
+	case MaxBatchSizeEdge:
+		return "maxBatchSize"
+	default:
+		return "unknown"
+	}
+}
+
+// InvocationInfo describes a single invocation of fn, passed to the
+// [WithOnInvoke] hook.
+type InvocationInfo struct {
+	// Edge is which edge triggered this invocation.
+	Edge Edge
+	// Coalesced is the number of debounced calls collapsed into this
+	// invocation since the previous one.
+	Coalesced int
+	// Wait is how long this invocation waited after the window that
+	// triggered it opened.
+	Wait time.Duration
+	// Duration is how long the call to fn itself took to return.
+	Duration time.Duration
+}
+
+// Stats holds cumulative counters for a debounced function, returned by a
+// control struct's Stats method.
+type Stats struct {
+	// CallsReceived is the total number of times the debounced function was
+	// called.
+	CallsReceived int64
+	// InvocationsMade is the total number of times fn was actually invoked.
+	InvocationsMade int64
+	// Cancels is the number of times Cancel cancelled a pending invocation.
+	Cancels int64
+	// Flushes is the number of times Flush invoked a pending invocation
+	// early.
+	Flushes int64
+	// MaxCoalesceRatio is the largest number of calls ever collapsed into a
+	// single invocation.
+	MaxCoalesceRatio int
+}
+
+// WithOnInvoke returns an Option that registers a hook called synchronously,
+// right after fn returns, with details about the invocation that just
+// happened.
+func WithOnInvoke(f func(InvocationInfo)) Option {
+	return func(o *options) {
+		o.onInvoke = f
+	}
+}
+
+// WithOnCoalesce returns an Option that registers a hook called whenever a
+// call to the debounced function is merged into an already-pending
+// invocation instead of triggering one immediately.
+func WithOnCoalesce(f func()) Option {
+	return func(o *options) {
+		o.onCoalesce = f
+	}
+}
+
+// WithOnCancel returns an Option that registers a hook called whenever
+// Cancel actually cancels a pending invocation.
+func WithOnCancel(f func()) Option {
+	return func(o *options) {
+		o.onCancel = f
+	}
+}
@@ -0,0 +1,69 @@
+package debounce_test
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	. "github.com/zmwangx/debounce"
+	"github.com/zmwangx/debounce/clocktest"
+)
+
+func TestObservabilityHooks(t *testing.T) {
+	Convey("observability hooks and Stats", t, func() {
+		Convey("should report invoke, coalesce, and cancel events", func() {
+			clock := clocktest.NewFakeClock()
+			var invocations []InvocationInfo
+			coalesceCount := 0
+			cancelCount := 0
+
+			debounced, control := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
+				return nil
+			}, 32*ms,
+				WithClock(clock),
+				WithOnInvoke(func(info InvocationInfo) { invocations = append(invocations, info) }),
+				WithOnCoalesce(func() { coalesceCount++ }),
+				WithOnCancel(func() { cancelCount++ }),
+			)
+
+			debounced()
+			debounced()
+			debounced()
+			So(coalesceCount, ShouldEqual, 2)
+
+			clock.Advance(32 * ms)
+			So(invocations, ShouldHaveLength, 1)
+			So(invocations[0].Edge, ShouldEqual, TrailingEdge)
+			So(invocations[0].Coalesced, ShouldEqual, 3)
+
+			debounced()
+			control.Cancel()
+			So(cancelCount, ShouldEqual, 1)
+
+			// Cancelling again with nothing pending should not re-fire the hook.
+			control.Cancel()
+			So(cancelCount, ShouldEqual, 1)
+		})
+
+		Convey("Stats should track cumulative counters", func() {
+			clock := clocktest.NewFakeClock()
+
+			debounced, control := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
+				return nil
+			}, 32*ms, WithClock(clock))
+
+			debounced()
+			debounced()
+			debounced()
+			clock.Advance(32 * ms)
+
+			debounced()
+			control.Flush()
+
+			stats := control.Stats()
+			So(stats.CallsReceived, ShouldEqual, 4)
+			So(stats.InvocationsMade, ShouldEqual, 2)
+			So(stats.Flushes, ShouldEqual, 1)
+			So(stats.MaxCoalesceRatio, ShouldEqual, 3)
+		})
+	})
+}
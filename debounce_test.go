@@ -1,12 +1,14 @@
 package debounce_test
 
 import (
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 	. "github.com/zmwangx/debounce"
+	"github.com/zmwangx/debounce/clocktest"
 )
 
 const ms = time.Millisecond
@@ -15,29 +17,35 @@ func TestDebounceWithCustomSignature(t *testing.T) {
 	Convey("DebounceWithCustomSignature", t, func() {
 		// The following tests were ported from
 		// https://github.com/lodash/lodash/blob/master/test/debounce-and-throttle.js.
+		//
+		// They run against a clocktest.FakeClock rather than real time, so the
+		// 32ms/64ms windows below describe virtual time advanced
+		// deterministically via clock.Advance, not real sleeps.
 
 		Convey("should support cancelling delayed calls", func() {
 			callCount := 0
+			clock := clocktest.NewFakeClock()
 
 			debounced, control := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
 				callCount++
 				return nil
-			}, 32*ms, WithLeading(false))
+			}, 32*ms, WithLeading(false), WithClock(clock))
 
 			debounced()
 			control.Cancel()
 
-			time.Sleep(64 * ms)
+			clock.Advance(64 * ms)
 			So(callCount, ShouldEqual, 0)
 		})
 
 		Convey("should reset `lastCalled` after cancelling", func() {
 			callCount := 0
+			clock := clocktest.NewFakeClock()
 
 			debounced, control := DebounceWithCustomSignature(func(args ...interface{}) int {
 				callCount++
 				return callCount
-			}, 32*ms, WithLeading(true))
+			}, 32*ms, WithLeading(true), WithClock(clock))
 
 			So(debounced(), ShouldEqual, 1)
 			control.Cancel()
@@ -45,37 +53,39 @@ func TestDebounceWithCustomSignature(t *testing.T) {
 			So(debounced(), ShouldEqual, 2)
 			debounced()
 
-			time.Sleep(64 * ms)
+			clock.Advance(64 * ms)
 			So(callCount, ShouldEqual, 3)
 		})
 
 		Convey("should support flushing delayed calls", func() {
 			callCount := 0
+			clock := clocktest.NewFakeClock()
 
 			debounced, control := DebounceWithCustomSignature(func(args ...interface{}) int {
 				callCount++
 				return callCount
-			}, 32*ms, WithLeading(false))
+			}, 32*ms, WithLeading(false), WithClock(clock))
 
 			debounced()
 			So(control.Flush(), ShouldEqual, 1)
 
-			time.Sleep(64 * ms)
+			clock.Advance(64 * ms)
 			So(callCount, ShouldEqual, 1)
 		})
 
 		Convey("should noop `cancel` and `flush` when nothing is queued", func() {
 			callCount := 0
+			clock := clocktest.NewFakeClock()
 
 			_, control := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
 				callCount++
 				return nil
-			}, 32*ms)
+			}, 32*ms, WithClock(clock))
 
 			control.Cancel()
 			So(control.Flush(), ShouldBeNil)
 
-			time.Sleep(64 * ms)
+			clock.Advance(64 * ms)
 			So(callCount, ShouldEqual, 0)
 		})
 
@@ -86,68 +96,73 @@ func TestDebounceWithCustomSignature(t *testing.T) {
 
 		Convey("should debounce a function", func() {
 			callCount := 0
+			clock := clocktest.NewFakeClock()
 
 			debounced, _ := DebounceWithCustomSignature(func(args ...string) string {
 				value := args[0]
 				callCount++
 				return value
-			}, 32*ms)
+			}, 32*ms, WithClock(clock))
 
 			results := []string{debounced("a"), debounced("b"), debounced("c")}
 			So(results, ShouldResemble, []string{"", "", ""})
 			So(callCount, ShouldEqual, 0)
 
-			time.Sleep(128 * ms)
+			clock.Advance(128 * ms)
 			So(callCount, ShouldEqual, 1)
 			results = []string{debounced("d"), debounced("e"), debounced("f")}
 			So(results, ShouldResemble, []string{"c", "c", "c"})
 			So(callCount, ShouldEqual, 1)
 
-			time.Sleep(128 * ms)
+			clock.Advance(128 * ms)
 			So(callCount, ShouldEqual, 2)
 		})
 
 		Convey("subsequent debounced calls return the last `func` result", func() {
+			clock := clocktest.NewFakeClock()
+
 			debounced, _ := DebounceWithCustomSignature(func(args ...string) string {
 				value := args[0]
 				return value
-			}, 32*ms)
+			}, 32*ms, WithClock(clock))
 			debounced("a")
 
-			time.Sleep(64 * ms)
+			clock.Advance(64 * ms)
 			So(debounced("b"), ShouldNotEqual, "b")
 
-			time.Sleep(64 * ms)
+			clock.Advance(64 * ms)
 			So(debounced("c"), ShouldNotEqual, "c")
 		})
 
 		Convey("should apply default options", func() {
 			callCount := 0
+			clock := clocktest.NewFakeClock()
 
 			debounced, _ := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
 				callCount++
 				return nil
-			}, 32*ms)
+			}, 32*ms, WithClock(clock))
 
 			debounced()
 			So(callCount, ShouldEqual, 0)
 
-			time.Sleep(64 * ms)
+			clock.Advance(64 * ms)
 			So(callCount, ShouldEqual, 1)
 		})
 
 		Convey("should support a `leading` option", func() {
 			callCounts := []int{0, 0}
+			clock := clocktest.NewFakeClock()
 
 			withLeading, _ := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
 				callCounts[0]++
 				return nil
-			}, 32*ms, WithLeading(true))
+			}, 32*ms, WithLeading(true), WithClock(clock))
 
 			withLeadingAndTrailing, _ := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
 				callCounts[1]++
 				return nil
-			}, 32*ms, WithLeading(true))
+			}, 32*ms, WithLeading(true), WithClock(clock))
 
 			withLeading()
 			So(callCounts[0], ShouldEqual, 1)
@@ -156,22 +171,24 @@ func TestDebounceWithCustomSignature(t *testing.T) {
 			withLeadingAndTrailing()
 			So(callCounts[1], ShouldEqual, 1)
 
-			time.Sleep(64 * ms)
+			clock.Advance(64 * ms)
 			So(callCounts, ShouldResemble, []int{1, 2})
 			withLeading()
 			So(callCounts[0], ShouldEqual, 2)
 		})
 
 		Convey("subsequent leading debounced calls return the last `func` result", func() {
+			clock := clocktest.NewFakeClock()
+
 			debounced, _ := DebounceWithCustomSignature(func(args ...string) string {
 				value := args[0]
 				return value
-			}, 32*ms, WithLeading(true), WithTrailing(false))
+			}, 32*ms, WithLeading(true), WithTrailing(false), WithClock(clock))
 
 			results := []string{debounced("a"), debounced("b")}
 			So(results, ShouldResemble, []string{"a", "a"})
 
-			time.Sleep(64 * ms)
+			clock.Advance(64 * ms)
 			results = []string{debounced("c"), debounced("d")}
 			So(results, ShouldResemble, []string{"c", "c"})
 		})
@@ -179,16 +196,17 @@ func TestDebounceWithCustomSignature(t *testing.T) {
 		Convey("should support a `trailing` option", func() {
 			withCount := 0
 			withoutCount := 0
+			clock := clocktest.NewFakeClock()
 
 			withTrailing, _ := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
 				withCount++
 				return nil
-			}, 32*ms, WithTrailing(true))
+			}, 32*ms, WithTrailing(true), WithClock(clock))
 
 			withoutTrailing, _ := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
 				withoutCount++
 				return nil
-			}, 32*ms, WithTrailing(false))
+			}, 32*ms, WithTrailing(false), WithClock(clock))
 
 			withTrailing()
 			So(withCount, ShouldEqual, 0)
@@ -196,52 +214,57 @@ func TestDebounceWithCustomSignature(t *testing.T) {
 			withoutTrailing()
 			So(withoutCount, ShouldEqual, 0)
 
-			time.Sleep(64 * ms)
+			clock.Advance(64 * ms)
 			So(withCount, ShouldEqual, 1)
 			So(withoutCount, ShouldEqual, 0)
 		})
 
 		Convey("should support a `maxWait` option", func() {
 			callCount := 0
+			clock := clocktest.NewFakeClock()
 
 			debounced, _ := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
 				callCount++
 				return nil
-			}, 32*ms, WithMaxWait(64*ms))
+			}, 32*ms, WithMaxWait(64*ms), WithClock(clock))
 
 			debounced()
 			debounced()
 			So(callCount, ShouldEqual, 0)
 
-			time.Sleep(128 * ms)
+			clock.Advance(128 * ms)
 			So(callCount, ShouldEqual, 1)
 			debounced()
 			debounced()
 			So(callCount, ShouldEqual, 1)
 
-			time.Sleep(128 * ms)
+			clock.Advance(128 * ms)
 			So(callCount, ShouldEqual, 2)
 		})
 
 		Convey("should support `maxWait` in a tight loop", func() {
-			limit := 320 * ms
+			const iterations = 320
 			var withCount int64
 			var withoutCount int64
+			clock := clocktest.NewFakeClock()
 
 			withMaxWait, _ := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
 				atomic.AddInt64(&withCount, 1)
 				return nil
-			}, 64*ms, WithMaxWait(128*ms))
+			}, 64*ms, WithMaxWait(128*ms), WithClock(clock))
 
 			withoutMaxWait, _ := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
 				atomic.AddInt64(&withoutCount, 1)
 				return nil
-			}, 96*ms)
+			}, 96*ms, WithClock(clock))
 
-			start := time.Now()
-			for time.Since(start) < limit {
+			// Simulate a tight loop spanning 320ms of virtual time, without
+			// sleeping on real wall-clock time: step the fake clock by 1ms
+			// between each pair of calls.
+			for i := 0; i < iterations; i++ {
 				withMaxWait()
 				withoutMaxWait()
+				clock.Advance(ms)
 			}
 			So(withCount, ShouldBeGreaterThan, 0)
 			So(withoutCount, ShouldEqual, 0)
@@ -249,59 +272,66 @@ func TestDebounceWithCustomSignature(t *testing.T) {
 
 		Convey("should queue a trailing call for subsequent debounced calls after `maxWait`", func() {
 			callCount := 0
+			clock := clocktest.NewFakeClock()
 
 			debounced, _ := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
 				callCount++
 				return nil
-			}, 200*ms, WithMaxWait(200*ms))
+			}, 200*ms, WithMaxWait(200*ms), WithClock(clock))
 
 			debounced()
 
-			time.Sleep(190 * ms)
+			clock.Advance(190 * ms)
 			debounced()
-			time.Sleep(10 * ms)
+			clock.Advance(10 * ms)
 			debounced()
-			time.Sleep(10 * ms)
+			clock.Advance(10 * ms)
 			debounced()
 
-			time.Sleep(300 * ms)
+			clock.Advance(300 * ms)
 			So(callCount, ShouldEqual, 2)
 		})
 
 		Convey("should cancel `maxDelayed` when `delayed` is invoked", func() {
 			callCount := 0
+			clock := clocktest.NewFakeClock()
 
 			debounced, _ := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
 				callCount++
 				return nil
-			}, 32*ms, WithMaxWait(64*ms))
+			}, 32*ms, WithMaxWait(64*ms), WithClock(clock))
 
 			debounced()
 
-			time.Sleep(128 * ms)
+			clock.Advance(128 * ms)
 			debounced()
 			So(callCount, ShouldEqual, 1)
 
-			time.Sleep(64 * ms)
+			clock.Advance(64 * ms)
 			So(callCount, ShouldEqual, 2)
 		})
 
 		Convey("should invoke the trailing call with the correct arguments", func() {
 			callCount := 0
 			var calledArgs []interface{}
+			clock := clocktest.NewFakeClock()
 
 			debounced, _ := DebounceWithCustomSignature(func(args ...interface{}) bool {
 				callCount++
 				calledArgs = args
 				return callCount != 2
-			}, 32*ms, WithLeading(true), WithMaxWait(64*ms))
-
-			for {
-				if !debounced("a", "b") {
-					break
-				}
+			}, 32*ms, WithLeading(true), WithMaxWait(64*ms), WithClock(clock))
+
+			// Simulate a tight loop that keeps the window open (by calling
+			// debounced faster than `wait` elapses) until maxWait forces a
+			// second, trailing invocation; checking callCount after every
+			// step, rather than relying on debounced's return value, avoids
+			// also calling debounced again in the same virtual instant the
+			// maxWait timer already fired.
+			for callCount < 2 {
+				debounced("a", "b")
+				clock.Advance(ms)
 			}
-			time.Sleep(64 * ms)
 			So(callCount, ShouldEqual, 2)
 			So(calledArgs, ShouldResemble, []interface{}{"a", "b"})
 		})
@@ -312,24 +342,28 @@ func TestDebounceWithCustomSignature(t *testing.T) {
 
 		Convey("should be thread-safe", func() {
 			var callCount int64
+			clock := clocktest.NewFakeClock()
 
 			debounced, _ := DebounceWithCustomSignature(func(args ...interface{}) interface{} {
 				atomic.AddInt64(&callCount, 1)
 				return nil
-			}, 32*ms)
+			}, 32*ms, WithClock(clock))
 
-			tightLoop := func() {
-				start := time.Now()
-				for time.Since(start) < 64*ms {
+			tightLoop := func(wg *sync.WaitGroup) {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
 					debounced()
 				}
 			}
 
-			go tightLoop()
-			go tightLoop()
-			go tightLoop()
+			var wg sync.WaitGroup
+			wg.Add(3)
+			go tightLoop(&wg)
+			go tightLoop(&wg)
+			go tightLoop(&wg)
+			wg.Wait()
 
-			time.Sleep(128 * ms)
+			clock.Advance(32 * ms)
 			So(callCount, ShouldEqual, 1)
 		})
 	})
@@ -0,0 +1,207 @@
+// Package ratelimit provides a token-bucket-backed alternative to
+// [debounce.Throttle], built on [golang.org/x/time/rate], for cases like
+// API-call smoothing where bursts must be honored but the long-run average
+// rate is bounded.
+package ratelimit
+
+import (
+	"sync"
+
+	"golang.org/x/time/rate"
+
+	"github.com/zmwangx/debounce"
+)
+
+// WaitMode controls what RateLimit does with a call that arrives when the
+// limiter has no token available.
+type WaitMode int
+
+const (
+	// Reserve coalesces the call with any already-pending one (last args
+	// win) and schedules it to run at the earliest time the limiter allows,
+	// via [rate.Limiter.Reserve]. This is the default.
+	Reserve WaitMode = iota
+	// Drop discards the call instead of scheduling it.
+	Drop
+)
+
+type options struct {
+	burst    int
+	waitMode WaitMode
+	clock    debounce.Clock
+}
+
+// Option configures [RateLimit].
+type Option func(*options)
+
+// WithBurst returns an Option that overrides the burst size passed
+// positionally to RateLimit.
+func WithBurst(burst int) Option {
+	return func(o *options) {
+		o.burst = burst
+	}
+}
+
+// WithWaitMode returns an Option that sets what happens to a call made while
+// the limiter has no token available. The default is [Reserve].
+func WithWaitMode(mode WaitMode) Option {
+	return func(o *options) {
+		o.waitMode = mode
+	}
+}
+
+// WithClock returns an Option that overrides the [debounce.Clock] used to
+// schedule a reserved call's delay. The default is the real system clock.
+// This only governs RateLimit's own scheduling; the underlying [rate.Limiter]
+// always tracks token refill against real time. It's primarily useful in
+// tests, paired with a fake clock such as the one provided by the
+// debounce/clocktest subpackage, to exercise scheduling behavior
+// deterministically instead of sleeping on real wall-clock time.
+func WithClock(clock debounce.Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// RateLimit creates a rate-limited function backed by a [rate.Limiter]
+// token bucket with rate r and burst size burst, as a principled alternative
+// to [debounce.Throttle]'s window-based approximation. When a call arrives
+// and the limiter has a token, fn runs immediately. When it doesn't, the
+// call is coalesced with any already-pending call (last args win, matching
+// [debounce.Debounce]'s coalescing model) and scheduled to run at the
+// earliest time the limiter allows, via [rate.Limiter.Reserve] — unless
+// [WithWaitMode]([Drop]) is given, in which case the call is simply dropped.
+//
+// The returned control struct integrates with the rest of the debounce API:
+// Cancel cancels any pending scheduled call, Flush runs it immediately, and
+// Pending reports whether one is scheduled.
+func RateLimit[T1, T2 any](
+	fn func(args ...T1) T2,
+	r rate.Limit,
+	burst int,
+	opts ...Option,
+) (limited func(args ...T1) T2, control debounce.ControlWithReturnValue[T2]) {
+	o := &options{
+		burst:    burst,
+		waitMode: Reserve,
+		clock:    debounce.RealClock{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	limiter := rate.NewLimiter(r, o.burst)
+	clock := o.clock
+
+	var lock sync.Mutex
+	var lastArgs []T1
+	var lastArgsActive bool
+	var timer debounce.StoppableTimer
+	var reservation *rate.Reservation
+	var result T2
+	var stats debounce.Stats
+
+	cancelPendingLocked := func() {
+		if timer != nil {
+			timer.Stop()
+			timer = nil
+		}
+		if reservation != nil {
+			reservation.Cancel()
+			reservation = nil
+		}
+		lastArgs = nil
+		lastArgsActive = false
+	}
+
+	limited = func(args ...T1) T2 {
+		lock.Lock()
+		defer lock.Unlock()
+		stats.CallsReceived++
+
+		if limiter.Allow() {
+			cancelPendingLocked()
+			result = fn(args...)
+			stats.InvocationsMade++
+			return result
+		}
+
+		if timer != nil {
+			// A call is already scheduled to run once the limiter allows it;
+			// the newest args win.
+			lastArgs = args
+			lastArgsActive = true
+			return result
+		}
+
+		if o.waitMode == Drop {
+			return result
+		}
+
+		res := limiter.Reserve()
+		if !res.OK() {
+			return result
+		}
+		lastArgs = args
+		lastArgsActive = true
+		reservation = res
+		timer = clock.AfterFunc(res.Delay(), func() {
+			lock.Lock()
+			defer lock.Unlock()
+			timer = nil
+			reservation = nil
+			if !lastArgsActive {
+				return
+			}
+			a := lastArgs
+			lastArgs = nil
+			lastArgsActive = false
+			result = fn(a...)
+			stats.InvocationsMade++
+		})
+		return result
+	}
+
+	cancel := func() {
+		lock.Lock()
+		defer lock.Unlock()
+		if timer != nil {
+			stats.Cancels++
+		}
+		cancelPendingLocked()
+	}
+
+	flush := func() T2 {
+		lock.Lock()
+		defer lock.Unlock()
+		if timer == nil || !lastArgsActive {
+			return result
+		}
+		stats.Flushes++
+		args := lastArgs
+		cancelPendingLocked()
+		result = fn(args...)
+		stats.InvocationsMade++
+		return result
+	}
+
+	pending := func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return timer != nil
+	}
+
+	statsFunc := func() debounce.Stats {
+		lock.Lock()
+		defer lock.Unlock()
+		return stats
+	}
+
+	control = debounce.ControlWithReturnValue[T2]{
+		Cancel:  cancel,
+		Flush:   flush,
+		Pending: pending,
+		Stats:   statsFunc,
+	}
+	return
+}
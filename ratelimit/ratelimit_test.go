@@ -0,0 +1,109 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"golang.org/x/time/rate"
+
+	"github.com/zmwangx/debounce/clocktest"
+	"github.com/zmwangx/debounce/ratelimit"
+)
+
+const ms = time.Millisecond
+
+func TestRateLimit(t *testing.T) {
+	Convey("RateLimit", t, func() {
+		Convey("should invoke immediately while tokens are available", func() {
+			callCount := 0
+
+			limited, _ := ratelimit.RateLimit(func(args ...interface{}) interface{} {
+				callCount++
+				return nil
+			}, rate.Every(time.Hour), 2)
+
+			limited()
+			limited()
+			So(callCount, ShouldEqual, 2)
+		})
+
+		Convey("should schedule a coalesced call once tokens run out", func() {
+			callCount := 0
+			var lastArg string
+			clock := clocktest.NewFakeClock()
+
+			limited, control := ratelimit.RateLimit(func(args ...string) interface{} {
+				callCount++
+				if len(args) > 0 {
+					lastArg = args[0]
+				}
+				return nil
+			}, rate.Every(20*ms), 1, ratelimit.WithClock(clock))
+
+			limited("a")
+			So(callCount, ShouldEqual, 1)
+
+			limited("b")
+			limited("c")
+			So(callCount, ShouldEqual, 1)
+			So(control.Pending(), ShouldBeTrue)
+
+			clock.Advance(60 * ms)
+			So(callCount, ShouldEqual, 2)
+			So(lastArg, ShouldEqual, "c")
+		})
+
+		Convey("should support Cancel", func() {
+			callCount := 0
+			clock := clocktest.NewFakeClock()
+
+			limited, control := ratelimit.RateLimit(func(args ...interface{}) interface{} {
+				callCount++
+				return nil
+			}, rate.Every(20*ms), 1, ratelimit.WithClock(clock))
+
+			limited()
+			limited()
+			control.Cancel()
+			So(control.Pending(), ShouldBeFalse)
+
+			clock.Advance(40 * ms)
+			So(callCount, ShouldEqual, 1)
+		})
+
+		Convey("should support Flush", func() {
+			callCount := 0
+
+			limited, control := ratelimit.RateLimit(func(args ...interface{}) interface{} {
+				callCount++
+				return nil
+			}, rate.Every(20*ms), 1)
+
+			limited()
+			limited()
+			So(control.Flush(), ShouldBeNil)
+			So(callCount, ShouldEqual, 2)
+			So(control.Pending(), ShouldBeFalse)
+		})
+
+		Convey("should drop excess calls with WithWaitMode(Drop)", func() {
+			callCount := 0
+			clock := clocktest.NewFakeClock()
+
+			limited, control := ratelimit.RateLimit(func(args ...interface{}) interface{} {
+				callCount++
+				return nil
+			}, rate.Every(20*ms), 1, ratelimit.WithWaitMode(ratelimit.Drop), ratelimit.WithClock(clock))
+
+			limited()
+			limited()
+			limited()
+			So(callCount, ShouldEqual, 1)
+			So(control.Pending(), ShouldBeFalse)
+
+			clock.Advance(40 * ms)
+			So(callCount, ShouldEqual, 1)
+		})
+	})
+}